@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// App holds the state needed to evaluate parsed Expr nodes.
+type App struct {
+	// sourcing holds the absolute paths of the lfrc files currently being
+	// evaluated via a source/include chain, innermost last, so re-entering
+	// one of them can be detected and reported as a cycle.
+	sourcing []string
+
+	// opts holds the current value of every option in optionSchema.
+	opts map[string]interface{}
+
+	// lastMessage is the most recently echoed statusbar message.
+	lastMessage string
+}
+
+// newApp creates an App with every option in optionSchema set to its
+// default value.
+func newApp() *App {
+	opts := make(map[string]interface{}, len(optionSchema))
+	for name, spec := range optionSchema {
+		opts[name] = spec.Default
+	}
+	return &App{opts: opts}
+}
+
+// echo records msg as the statusbar message.
+func (app *App) echo(msg string) {
+	app.lastMessage = msg
+}
+
+// source resolves path relative to the directory of the file that
+// contains the enclosing `source` directive (pos.Filename), expands it as
+// a glob, and evaluates the matching files in lexicographical order. It is
+// safe to call both while loading the startup lfrc and from within a cmd
+// body, so fragments can be hot-reloaded with e.g. `:source ~/.config/lf/keys.lf`.
+func (app *App) source(path string, pos Pos) error {
+	path = expandPath(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(pos.Filename), path)
+	}
+
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return fmt.Errorf("%s: source: %s", pos, err)
+	}
+	if matches == nil {
+		matches = []string{path}
+	}
+	sort.Strings(matches)
+
+	for _, match := range matches {
+		if err := app.sourceFile(match, pos); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (app *App) sourceFile(path string, pos Pos) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%s: source: %s", pos, err)
+	}
+
+	for _, p := range app.sourcing {
+		if p == abs {
+			return fmt.Errorf("%s: source: include cycle on %s", pos, abs)
+		}
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return fmt.Errorf("%s: source: %s", pos, err)
+	}
+	defer f.Close()
+
+	app.sourcing = append(app.sourcing, abs)
+	defer func() { app.sourcing = app.sourcing[:len(app.sourcing)-1] }()
+
+	p := newParser(f, abs)
+	for p.parse() {
+		if err := p.expr.eval(app, nil); err != nil {
+			return err
+		}
+	}
+
+	return p.err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// dumper implements Visitor to print an indented structural dump of an
+// Expr tree, one line per node: its kind, position, and String form.
+type dumper struct {
+	w     io.Writer
+	depth int
+}
+
+func (d *dumper) Visit(e Expr) Visitor {
+	fmt.Fprintf(d.w, "%s%T @ %s: %s\n", strings.Repeat("  ", d.depth), e, e.Pos(), e)
+	return &dumper{w: d.w, depth: d.depth + 1}
+}
+
+// Fdump writes an indented structural dump of the Expr tree rooted at e to
+// w, so a `:{{ ... }}` list or a `cmd`/`map` body can be inspected without
+// running it.
+func Fdump(w io.Writer, e Expr) {
+	Walk(e, &dumper{w: w})
+}
+
+// CheckConfig parses the lfrc file at path without evaluating it, reporting
+// the first error encountered with its file:line:col position. If dump is
+// true, every top-level Expr parsed before the error (or the whole file, on
+// success) is written to stdout via Fdump. No command-line flag in this
+// package calls it.
+func CheckConfig(path string, dump bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("check-config: %s", err)
+	}
+	defer f.Close()
+
+	p := newParser(f, path)
+
+	var exprs []Expr
+	for p.parse() {
+		exprs = append(exprs, p.expr)
+	}
+
+	if dump {
+		for _, e := range exprs {
+			Fdump(os.Stdout, e)
+		}
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// eval looks up e.opt in optionSchema and either stores the coerced and
+// validated value (plain form), flips a bool option (e.opt!), or reports
+// its current value to the statusbar (e.opt?).
+func (e *SetExpr) eval(app *App, args []string) error {
+	spec, ok := optionSchema[e.opt]
+	if !ok {
+		return fmt.Errorf("%s: unknown option %q", e.pos, e.opt)
+	}
+
+	if e.query {
+		app.echo(fmt.Sprintf("%s=%v", e.opt, app.opts[e.opt]))
+		return nil
+	}
+
+	if e.toggle {
+		if spec.Type != TypeBool {
+			return fmt.Errorf("%s: option '%s' is not a bool", e.pos, e.opt)
+		}
+		app.opts[e.opt] = !app.opts[e.opt].(bool)
+		return nil
+	}
+
+	val, err := coerce(spec.Type, e.val)
+	if err != nil {
+		return fmt.Errorf("%s: option '%s' expects %s, got %q", e.pos, e.opt, spec.Type, e.val)
+	}
+
+	if spec.Validate != nil {
+		if err := spec.Validate(val); err != nil {
+			return fmt.Errorf("%s: option '%s': %s", e.pos, e.opt, err)
+		}
+	}
+
+	app.opts[e.opt] = val
+
+	return nil
+}
+
+// TODO: bind e.expr to e.keys in the key map
+func (e *MapExpr) eval(app *App, args []string) error {
+	return nil
+}
+
+// TODO: register e.expr under e.name so it can be called by CallExpr
+func (e *CmdExpr) eval(app *App, args []string) error {
+	return nil
+}
+
+// TODO: dispatch to a builtin or a registered cmd by e.name
+func (e *CallExpr) eval(app *App, args []string) error {
+	return nil
+}
+
+// TODO: run e.expr through the shell according to e.pref
+func (e *ExecExpr) eval(app *App, args []string) error {
+	return nil
+}
+
+// eval resolves and evaluates the file (or glob of files) named by e.path,
+// detecting include cycles via app.sourcing.
+func (e *SourceExpr) eval(app *App, args []string) error {
+	return app.source(e.path, e.pos)
+}
+
+// eval evaluates each expr in e in order, stopping at the first one that
+// fails. Each Expr's eval already prefixes its own error with its Pos, so
+// the error is returned as-is rather than prefixed again here.
+func (e *ListExpr) eval(app *App, args []string) error {
+	for _, expr := range e.exprs {
+		if err := expr.eval(app, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
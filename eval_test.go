@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestSetExprEvalToggle(t *testing.T) {
+	app := newApp()
+
+	e := &SetExpr{pos: InitPos, opt: "hidden", toggle: true}
+	if err := e.eval(app, nil); err != nil {
+		t.Fatalf("eval: %s", err)
+	}
+	if app.opts["hidden"] != true {
+		t.Fatalf("opts[hidden] = %v, want true", app.opts["hidden"])
+	}
+
+	if err := e.eval(app, nil); err != nil {
+		t.Fatalf("eval: %s", err)
+	}
+	if app.opts["hidden"] != false {
+		t.Fatalf("opts[hidden] = %v, want false after second toggle", app.opts["hidden"])
+	}
+}
+
+func TestSetExprEvalToggleRejectsNonBool(t *testing.T) {
+	app := newApp()
+
+	e := &SetExpr{pos: InitPos, opt: "sortby", toggle: true}
+	if err := e.eval(app, nil); err == nil {
+		t.Fatalf("eval: expected error toggling a non-bool option, got none")
+	}
+}
+
+func TestSetExprEvalQuery(t *testing.T) {
+	app := newApp()
+
+	e := &SetExpr{pos: InitPos, opt: "sortby", query: true}
+	if err := e.eval(app, nil); err != nil {
+		t.Fatalf("eval: %s", err)
+	}
+	if app.lastMessage != "sortby=natural" {
+		t.Fatalf("lastMessage = %q, want %q", app.lastMessage, "sortby=natural")
+	}
+}
+
+func TestSetExprEvalUnknownOption(t *testing.T) {
+	app := newApp()
+
+	e := &SetExpr{pos: InitPos, opt: "notanoption", val: "x"}
+	if err := e.eval(app, nil); err == nil {
+		t.Fatalf("eval: expected error for unknown option, got none")
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	if v, err := coerce(TypeBool, "yes"); err != nil || v != true {
+		t.Fatalf("coerce(TypeBool, %q) = %v, %v, want true, nil", "yes", v, err)
+	}
+	if v, err := coerce(TypeBool, "off"); err != nil || v != false {
+		t.Fatalf("coerce(TypeBool, %q) = %v, %v, want false, nil", "off", v, err)
+	}
+	if _, err := coerce(TypeBool, "maybe"); err == nil {
+		t.Fatalf("coerce(TypeBool, %q): expected error, got none", "maybe")
+	}
+	if v, err := coerce(TypeInt, "42"); err != nil || v != 42 {
+		t.Fatalf("coerce(TypeInt, %q) = %v, %v, want 42, nil", "42", v, err)
+	}
+	if v, err := coerce(TypeSize, "4_MiB"); err != nil || v != int64(4*1024*1024) {
+		t.Fatalf("coerce(TypeSize, %q) = %v, %v, want %d, nil", "4_MiB", v, err, 4*1024*1024)
+	}
+}
@@ -3,48 +3,137 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
 
 func isRoot(name string) bool { return path.Dir(name) == name }
 
-// This function converts a size in bytes to a human readable form. For this
-// purpose metric suffixes are used (e.g. 1K = 1000). For values less than 10
-// the first significant digit is shown, otherwise it is hidden. Numbers are
-// always rounded down. For these reasons this function always show somewhat
+// expandPath expands a leading "~" in path to the current user's home
+// directory, leaving absolute and relative paths untouched otherwise.
+func expandPath(path string) string {
+	if path == "~" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home
+		}
+		return path
+	}
+	if strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// This function converts a size in bytes to a human readable form using
+// metric suffixes (e.g. 1K = 1000). For values less than 10 the first
+// significant digit is shown, otherwise it is hidden. Numbers are always
+// rounded down. For these reasons this function always show somewhat
 // smaller values but it should be fine for most human beings.
-func humanize(size int64) string {
-	if size < 1000 {
+func humanize(size int64) string { return humanizeBase(size, 1000) }
+
+// humanizeBase is like humanize but lets the caller choose base 1000 for
+// SI suffixes (K, M, G, ...) or base 1024 for IEC suffixes (Ki, Mi, Gi,
+// ...), so options such as `set info size` can present sizes using either
+// convention.
+func humanizeBase(size int64, base int) string {
+	if size < int64(base) {
 		return fmt.Sprintf("%d", size)
 	}
 
-	suffix := []string{
-		"K", // kilo
-		"M", // mega
-		"G", // giga
-		"T", // tera
-		"P", // peta
-		"E", // exa
-		"Z", // zeta
-		"Y", // yotta
+	var suffix []string
+	switch base {
+	case 1024:
+		suffix = []string{"Ki", "Mi", "Gi", "Ti", "Pi", "Ei", "Zi", "Yi"}
+	default:
+		suffix = []string{"K", "M", "G", "T", "P", "E", "Z", "Y"}
 	}
 
-	curr := float64(size) / 1000
+	curr := float64(size) / float64(base)
 	for _, s := range suffix {
 		if curr < 10 {
 			return fmt.Sprintf("%.1f%s", curr-0.0499, s)
 		} else if curr < 1000 {
 			return fmt.Sprintf("%d%s", int(curr), s)
 		}
-		curr /= 1000
+		curr /= float64(base)
 	}
 
 	return ""
 }
 
+// sizeSuffixes maps the SI (×1000) and IEC (×1024) suffixes accepted by
+// parseSize to their multiplier, longest first so e.g. "Ki" is tried
+// before "K" would be. Matching is case-insensitive so this also accepts
+// the upper-case "K"/"M"/"G" humanizeBase emits for base 1000.
+var sizeSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"k", 1000},
+	{"m", 1000 * 1000},
+	{"g", 1000 * 1000 * 1000},
+}
+
+// parseSize parses a human-written size such as "10k", "4_MiB", or
+// "1_048_576" into a byte count. It accepts an optional trailing "B", SI
+// suffixes (k, m, g = ×1000), IEC suffixes (Ki, Mi, Gi = ×1024), a
+// fractional mantissa (e.g. "4.0Mi"), and underscores as digit separators
+// (e.g. "1_048_576"). The mantissa is parsed as a float rather than an
+// integer so that values produced by humanizeBase, which truncates to one
+// decimal place below its tier's first ten units, parse back without error.
+func parseSize(s string) (int64, error) {
+	mantissa := strings.TrimSuffix(s, "B")
+	mult := int64(1)
+	matched := false
+
+	for _, suf := range sizeSuffixes {
+		if i := len(mantissa) - len(suf.suffix); i >= 0 && strings.EqualFold(mantissa[i:], suf.suffix) {
+			mantissa = mantissa[:i]
+			mult = suf.mult
+			matched = true
+			break
+		}
+	}
+
+	if matched {
+		// The single underscore separating the number from its unit, as
+		// in "4_MiB", is not a digit separator and is only valid here.
+		mantissa = strings.TrimSuffix(mantissa, "_")
+	}
+
+	digits, err := trimDigitSeparators(mantissa)
+	if err != nil {
+		return 0, fmt.Errorf("parsing size %q: %s", s, err)
+	}
+
+	n, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing size %q: %s", s, err)
+	}
+
+	return int64(n * float64(mult)), nil
+}
+
+// trimDigitSeparators strips underscores used as digit separators, e.g.
+// "1_048_576" -> "1048576", rejecting a leading, trailing, or doubled
+// underscore the same way Go numeric literals do.
+func trimDigitSeparators(s string) (string, error) {
+	if s == "" || s[0] == '_' || s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return "", fmt.Errorf("invalid digit separators in %q", s)
+	}
+	return strings.ReplaceAll(s, "_", ""), nil
+}
+
 // This function extracts numbers from a string and returns with the rest.
 // It is used for numeric sorting of files when the file name consists of
 // both digits and letters.
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"1_048_576", 1048576},
+		{"10k", 10000},
+		{"10Ki", 10240},
+		{"4_MiB", 4 * 1024 * 1024},
+		{"4.0Mi", 4 * 1024 * 1024},
+		{"1g", 1000 * 1000 * 1000},
+		{"1Gi", 1 << 30},
+	}
+
+	for _, c := range cases {
+		got, err := parseSize(c.in)
+		if err != nil {
+			t.Errorf("parseSize(%q): unexpected error: %s", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parseSize(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseSizeInvalid(t *testing.T) {
+	cases := []string{"_1000", "1000_", "1__000", "", "foo"}
+
+	for _, in := range cases {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q): expected error, got none", in)
+		}
+	}
+}
+
+func TestParseSizeRoundTripsThroughHumanizeBase(t *testing.T) {
+	cases := []struct {
+		size int64
+		base int
+	}{
+		{4 * 1024 * 1024, 1024},
+		{10 * 1024, 1024},
+		{1500, 1000},
+		{999, 1000},
+	}
+
+	for _, c := range cases {
+		h := humanizeBase(c.size, c.base)
+		if _, err := parseSize(h); err != nil {
+			t.Errorf("parseSize(humanizeBase(%d, %d)) = parseSize(%q): unexpected error: %s",
+				c.size, c.base, h, err)
+		}
+	}
+}
+
+func TestTrimDigitSeparators(t *testing.T) {
+	ok := map[string]string{
+		"1048576":     "1048576",
+		"1_048_576":   "1048576",
+		"1_000_000_0": "10000000",
+	}
+	for in, want := range ok {
+		got, err := trimDigitSeparators(in)
+		if err != nil {
+			t.Errorf("trimDigitSeparators(%q): unexpected error: %s", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("trimDigitSeparators(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	invalid := []string{"", "_1000", "1000_", "1__000"}
+	for _, in := range invalid {
+		if _, err := trimDigitSeparators(in); err == nil {
+			t.Errorf("trimDigitSeparators(%q): expected error, got none", in)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type identifies the value type of a configurable option.
+type Type int
+
+const (
+	TypeBool Type = iota
+	TypeInt
+	TypeString
+	TypeDuration
+	TypeStringList
+	TypeSize
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeString:
+		return "string"
+	case TypeDuration:
+		return "duration"
+	case TypeStringList:
+		return "string list"
+	case TypeSize:
+		return "size"
+	}
+	return "unknown"
+}
+
+// OptionSpec describes a single `set`-able option: its value type, its
+// default, and an optional validator run on the coerced value.
+type OptionSpec struct {
+	Type     Type
+	Default  interface{}
+	Validate func(interface{}) error
+}
+
+// optionSchema is the registry of every option known to `set`, keyed by
+// its name as written in an lfrc file.
+var optionSchema = map[string]OptionSpec{
+	"hidden":    {Type: TypeBool, Default: false},
+	"preview":   {Type: TypeBool, Default: true},
+	"reverse":   {Type: TypeBool, Default: false},
+	"dirfirst":  {Type: TypeBool, Default: true},
+	"sortby":    {Type: TypeString, Default: "natural"},
+	"period":    {Type: TypeDuration, Default: time.Second},
+	"shellopts": {Type: TypeStringList, Default: []string(nil)},
+	"scrolloff": {Type: TypeSize, Default: int64(0), Validate: func(v interface{}) error {
+		if v.(int64) < 0 {
+			return fmt.Errorf("must be non-negative")
+		}
+		return nil
+	}},
+	"previewsize": {Type: TypeSize, Default: int64(0)},
+}
+
+// parseBool accepts the spellings commonly used across lfrc options:
+// true/false, yes/no, on/off, 1/0.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid bool %q", s)
+}
+
+// coerce converts the raw text of a `set` value into the Go value implied
+// by typ.
+func coerce(typ Type, val string) (interface{}, error) {
+	switch typ {
+	case TypeBool:
+		return parseBool(val)
+	case TypeInt:
+		return strconv.Atoi(val)
+	case TypeString:
+		return val, nil
+	case TypeDuration:
+		return time.ParseDuration(val)
+	case TypeStringList:
+		var list []string
+		for _, s := range strings.Split(val, ",") {
+			list = append(list, strings.TrimSpace(s))
+		}
+		return list, nil
+	case TypeSize:
+		return parseSize(val)
+	}
+	return nil, fmt.Errorf("unknown option type %d", typ)
+}
@@ -2,18 +2,21 @@ package main
 
 // Grammar of the language used in the evaluator
 //
-// Expr     = SetExpr
-//          | MapExpr
-//          | CmdExpr
-//          | CallExpr
-//          | ExecExpr
-//          | ListExpr
+// Expr       = SetExpr
+//            | MapExpr
+//            | CmdExpr
+//            | CallExpr
+//            | ExecExpr
+//            | ListExpr
+//            | SourceExpr
 //
-// SetExpr  = 'set' <opt> <val> ';'
+// SetExpr    = 'set' <opt> <val> ';'
 //
-// MapExpr  = 'map' <keys> Expr ';'
+// MapExpr    = 'map' <keys> Expr ';'
 //
-// CmdExpr  = 'cmd' <name> Expr ';'
+// CmdExpr    = 'cmd' <name> Expr ';'
+//
+// SourceExpr = 'source' <path> ';'
 //
 // CallExpr = <name> <args> ';'
 //
@@ -32,54 +35,80 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 )
 
 type Expr interface {
 	String() string
+	Pos() Pos
 
-	eval(app *App, args []string)
+	eval(app *App, args []string) error
 	// TODO: add a bind method to avoid passing args in eval
 }
 
 type SetExpr struct {
-	opt string
-	val string
+	pos    Pos
+	opt    string
+	val    string
+	toggle bool // set opt! -- flip a bool option
+	query  bool // set opt? -- report the current value to the statusbar
 }
 
-func (e *SetExpr) String() string { return fmt.Sprintf("set %s %s", e.opt, e.val) }
+func (e *SetExpr) String() string {
+	switch {
+	case e.toggle:
+		return fmt.Sprintf("set %s!", e.opt)
+	case e.query:
+		return fmt.Sprintf("set %s?", e.opt)
+	default:
+		return fmt.Sprintf("set %s %s", e.opt, e.val)
+	}
+}
+func (e *SetExpr) Pos() Pos { return e.pos }
 
 type MapExpr struct {
+	pos  Pos
 	keys string
 	expr Expr
 }
 
 func (e *MapExpr) String() string { return fmt.Sprintf("map %s %s", e.keys, e.expr) }
+func (e *MapExpr) Pos() Pos       { return e.pos }
 
 type CmdExpr struct {
+	pos  Pos
 	name string
 	expr Expr
 }
 
 func (e *CmdExpr) String() string { return fmt.Sprintf("cmd %s %s", e.name, e.expr) }
+func (e *CmdExpr) Pos() Pos       { return e.pos }
 
 type CallExpr struct {
+	pos  Pos
 	name string
 	args []string
 }
 
 func (e *CallExpr) String() string { return fmt.Sprintf("%s -- %s", e.name, e.args) }
+func (e *CallExpr) Pos() Pos       { return e.pos }
 
 type ExecExpr struct {
+	pos  Pos
 	pref string
 	expr string
 }
 
 func (e *ExecExpr) String() string { return fmt.Sprintf("%s %s", e.pref, e.expr) }
+func (e *ExecExpr) Pos() Pos       { return e.pos }
 
 type ListExpr struct {
+	pos   Pos
 	exprs []Expr
 }
 
+func (e *ListExpr) Pos() Pos { return e.pos }
+
 func (e *ListExpr) String() string {
 	buf := []byte{':', '{', '{', ' '}
 	for _, expr := range e.exprs {
@@ -90,28 +119,54 @@ func (e *ListExpr) String() string {
 	return string(buf)
 }
 
+type SourceExpr struct {
+	pos  Pos
+	path string
+}
+
+func (e *SourceExpr) String() string { return fmt.Sprintf("source %s", e.path) }
+func (e *SourceExpr) Pos() Pos       { return e.pos }
+
 type Parser struct {
-	scanner *Scanner
-	expr    Expr
-	err     error
+	scanner  *Scanner
+	filename string
+	expr     Expr
+	err      error
 }
 
-func newParser(r io.Reader) *Parser {
+// newParser creates a Parser reading lfrc source from r. filename is
+// recorded on every Pos produced while parsing r, so errors and Expr nodes
+// originating from a `source`-d file point back at that file rather than
+// the file that sourced it.
+func newParser(r io.Reader, filename string) *Parser {
 	scanner := newScanner(r)
 
 	scanner.scan()
 
 	return &Parser{
-		scanner: scanner,
+		scanner:  scanner,
+		filename: filename,
 	}
 }
 
+// pos returns the position of the scanner's current token, stamped with
+// the parser's filename.
+func (p *Parser) pos() Pos {
+	pos := p.scanner.pos
+	pos.Filename = p.filename
+	return pos
+}
+
+func (p *Parser) errorf(format string, args ...interface{}) {
+	p.err = fmt.Errorf("%s: %s", p.pos(), fmt.Sprintf(format, args...))
+}
+
 func (p *Parser) parseExpr() Expr {
 	s := p.scanner
 
 	var result Expr
 
-	// TODO: syntax error check
+	pos := p.pos()
 
 	switch s.typ {
 	case TokenEOF:
@@ -124,32 +179,71 @@ func (p *Parser) parseExpr() Expr {
 			s.scan()
 			opt := s.tok
 
+			var toggle, query bool
+			suffix := ""
+			switch {
+			case strings.HasSuffix(opt, "!"):
+				opt, toggle, suffix = strings.TrimSuffix(opt, "!"), true, "!"
+			case strings.HasSuffix(opt, "?"):
+				opt, query, suffix = strings.TrimSuffix(opt, "?"), true, "?"
+			}
+
 			s.scan()
 			var val string
 			if s.typ != TokenSemicolon {
+				if toggle || query {
+					p.errorf("unexpected value %q after 'set %s%s'", s.tok, opt, suffix)
+					return nil
+				}
 				val = s.tok
 				s.scan()
 			}
 
 			s.scan()
 
-			result = &SetExpr{opt, val}
+			result = &SetExpr{pos, opt, val, toggle, query}
 		case "map":
 			s.scan()
+			if s.typ != TokenIdent {
+				p.errorf("expected key sequence after 'map', got %q", s.tok)
+				return nil
+			}
 			keys := s.tok
 
 			s.scan()
 			expr := p.parseExpr()
+			if expr == nil && p.err != nil {
+				return nil
+			}
 
-			result = &MapExpr{keys, expr}
+			result = &MapExpr{pos, keys, expr}
 		case "cmd":
 			s.scan()
+			if s.typ != TokenIdent {
+				p.errorf("expected name after 'cmd', got %q", s.tok)
+				return nil
+			}
 			name := s.tok
 
 			s.scan()
 			expr := p.parseExpr()
+			if expr == nil && p.err != nil {
+				return nil
+			}
+
+			result = &CmdExpr{pos, name, expr}
+		case "source":
+			s.scan()
+			if s.typ != TokenIdent {
+				p.errorf("expected path after 'source', got %q", s.tok)
+				return nil
+			}
+			path := s.tok
+
+			s.scan()
+			s.scan()
 
-			result = &CmdExpr{name, expr}
+			result = &SourceExpr{pos, path}
 		default:
 			name := s.tok
 
@@ -161,11 +255,11 @@ func (p *Parser) parseExpr() Expr {
 			s.scan()
 
 			if s.err != nil {
-				p.err = fmt.Errorf("parsing: %s", s.err)
+				p.errorf("%s", s.err)
 				return nil
 			}
 
-			result = &CallExpr{name, args}
+			result = &CallExpr{pos, name, args}
 		}
 	case TokenColon:
 		s.scan()
@@ -199,7 +293,7 @@ func (p *Parser) parseExpr() Expr {
 
 		s.scan()
 
-		result = &ListExpr{exprs}
+		result = &ListExpr{pos, exprs}
 	case TokenPrefix:
 		pref := s.tok
 
@@ -213,20 +307,22 @@ func (p *Parser) parseExpr() Expr {
 		} else if s.typ == TokenCommand {
 			expr = s.tok
 		} else {
-			// TODO: handle error
+			p.errorf("expected '{{' or a command after %q, got %q", pref, s.tok)
+			return nil
 		}
 
 		s.scan()
 		s.scan()
 
 		if s.err != nil {
-			p.err = fmt.Errorf("parsing: %s", s.err)
+			p.errorf("%s", s.err)
 			return nil
 		}
 
-		result = &ExecExpr{pref, expr}
+		result = &ExecExpr{pos, pref, expr}
 	default:
-		// TODO: handle error
+		p.errorf("unexpected token %q", s.tok)
+		return nil
 	}
 
 	log.Println("parsed:", result)
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSetToggle(t *testing.T) {
+	p := newParser(strings.NewReader("set hidden!;"), "lfrc")
+	if !p.parse() {
+		t.Fatalf("parse: %s", p.err)
+	}
+	e, ok := p.expr.(*SetExpr)
+	if !ok {
+		t.Fatalf("parse: got %T, want *SetExpr", p.expr)
+	}
+	if e.opt != "hidden" || !e.toggle || e.query || e.val != "" {
+		t.Fatalf("parse: got %+v, want opt=hidden toggle=true", e)
+	}
+}
+
+func TestParseSetQuery(t *testing.T) {
+	p := newParser(strings.NewReader("set hidden?;"), "lfrc")
+	if !p.parse() {
+		t.Fatalf("parse: %s", p.err)
+	}
+	e, ok := p.expr.(*SetExpr)
+	if !ok {
+		t.Fatalf("parse: got %T, want *SetExpr", p.expr)
+	}
+	if e.opt != "hidden" || !e.query || e.toggle || e.val != "" {
+		t.Fatalf("parse: got %+v, want opt=hidden query=true", e)
+	}
+}
+
+func TestParseSetToggleRejectsValue(t *testing.T) {
+	p := newParser(strings.NewReader("set hidden! true;"), "lfrc")
+	if p.parse() {
+		t.Fatalf("parse: expected error for value after 'set hidden!', got %+v", p.expr)
+	}
+	if p.err == nil {
+		t.Fatalf("parse: expected error for value after 'set hidden!', got none")
+	}
+}
+
+func TestParseSetQueryRejectsValue(t *testing.T) {
+	p := newParser(strings.NewReader("set hidden? true;"), "lfrc")
+	if p.parse() {
+		t.Fatalf("parse: expected error for value after 'set hidden?', got %+v", p.expr)
+	}
+	if p.err == nil {
+		t.Fatalf("parse: expected error for value after 'set hidden?', got none")
+	}
+}
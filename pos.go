@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// Pos identifies a location in an lfrc source file, used to annotate parse
+// errors and Expr nodes so diagnostics can point back at the offending
+// file, line, and column. Line and Column are both 1-based.
+type Pos struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+// InitPos is the position of the first rune of a file.
+var InitPos = Pos{Line: 1, Column: 1}
+
+func (p Pos) String() string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"io"
+)
+
+// TokenType classifies the tokens produced by Scanner.
+type TokenType int
+
+const (
+	TokenEOF TokenType = iota
+	TokenEOL
+	TokenSemicolon
+	TokenColon
+	TokenLBraces
+	TokenRBraces
+	TokenPrefix
+	TokenIdent
+	TokenCommand
+)
+
+// Scanner tokenizes an lfrc source file one token at a time, tracking the
+// position of the token it last produced so the Parser can annotate errors
+// and Expr nodes with a Pos.
+type Scanner struct {
+	r          *bufio.Reader
+	cursor     Pos // position of the rune not yet read
+	prevCursor Pos // cursor as it was before the last readRune, for unreadRune
+
+	rawBraces bool // next scan() reads raw text up to the closing '}}'
+
+	pos Pos // position of the current token
+	typ TokenType
+	tok string
+	err error
+}
+
+func newScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		r:      bufio.NewReader(r),
+		cursor: InitPos,
+	}
+}
+
+func isSpace(r rune) bool { return r == ' ' || r == '\t' || r == '\r' }
+
+func (s *Scanner) readRune() (rune, error) {
+	r, _, err := s.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	s.prevCursor = s.cursor
+	if r == '\n' {
+		s.cursor.Line++
+		s.cursor.Column = 1
+	} else {
+		s.cursor.Column++
+	}
+	return r, nil
+}
+
+// unreadRune undoes the single most recent readRune, including its effect
+// on cursor (which, for a '\n', means restoring the previous line, not
+// just decrementing the column).
+func (s *Scanner) unreadRune() {
+	if err := s.r.UnreadRune(); err != nil {
+		return
+	}
+	s.cursor = s.prevCursor
+}
+
+func (s *Scanner) skipSpaces() {
+	for {
+		r, err := s.readRune()
+		if err != nil {
+			return
+		}
+		if !isSpace(r) {
+			s.unreadRune()
+			return
+		}
+	}
+}
+
+// scan reads the next token, storing its type and text in s.typ and s.tok
+// and its starting position in s.pos. It returns false at end of file.
+func (s *Scanner) scan() bool {
+	wasPrefix := s.typ == TokenPrefix
+
+	if s.rawBraces {
+		s.rawBraces = false
+		return s.scanRawBraces()
+	}
+	if wasPrefix {
+		return s.scanAfterPrefix()
+	}
+	return s.scanNormal()
+}
+
+func (s *Scanner) scanNormal() bool {
+	s.skipSpaces()
+
+	start := s.cursor
+
+	r, err := s.readRune()
+	if err != nil {
+		s.pos, s.typ, s.tok = start, TokenEOF, ""
+		return false
+	}
+
+	switch r {
+	case '\n':
+		s.pos, s.typ, s.tok = start, TokenEOL, "\n"
+	case ';':
+		s.pos, s.typ, s.tok = start, TokenSemicolon, ";"
+	case ':':
+		s.pos, s.typ, s.tok = start, TokenColon, ":"
+	case '{':
+		if r2, err := s.readRune(); err == nil && r2 == '{' {
+			s.pos, s.typ, s.tok = start, TokenLBraces, "{{"
+		} else {
+			if err == nil {
+				s.unreadRune()
+			}
+			s.pos, s.typ, s.tok = start, TokenIdent, "{"
+		}
+	case '}':
+		if r2, err := s.readRune(); err == nil && r2 == '}' {
+			s.pos, s.typ, s.tok = start, TokenRBraces, "}}"
+		} else {
+			if err == nil {
+				s.unreadRune()
+			}
+			s.pos, s.typ, s.tok = start, TokenIdent, "}"
+		}
+	case '$', '!', '&', '/', '?':
+		s.pos, s.typ, s.tok = start, TokenPrefix, string(r)
+	default:
+		buf := []rune{r}
+		for {
+			r, err := s.readRune()
+			if err != nil {
+				break
+			}
+			if isSpace(r) || r == '\n' || r == ';' {
+				s.unreadRune()
+				break
+			}
+			buf = append(buf, r)
+		}
+		s.pos, s.typ, s.tok = start, TokenIdent, string(buf)
+	}
+
+	return true
+}
+
+// scanAfterPrefix decides what follows a Prefix token: either a '{{ ... }}'
+// block, whose contents are read raw by a later scanRawBraces, or the rest
+// of the line read verbatim as a single TokenCommand.
+func (s *Scanner) scanAfterPrefix() bool {
+	s.skipSpaces()
+
+	start := s.cursor
+
+	if b, err := s.r.Peek(2); err == nil && string(b) == "{{" {
+		s.readRune()
+		s.readRune()
+		s.pos, s.typ, s.tok = start, TokenLBraces, "{{"
+		s.rawBraces = true
+		return true
+	}
+
+	var buf []rune
+	for {
+		r, err := s.readRune()
+		if err != nil {
+			break
+		}
+		if r == '\n' {
+			s.unreadRune()
+			break
+		}
+		buf = append(buf, r)
+	}
+
+	s.pos, s.typ, s.tok = start, TokenCommand, string(buf)
+	return true
+}
+
+// scanRawBraces reads everything up to (but not including) the closing
+// '}}' of a '{{ ... }}' block as a single token, so the enclosed shell
+// command or list expression text is not re-tokenized.
+func (s *Scanner) scanRawBraces() bool {
+	start := s.cursor
+
+	var buf []rune
+	for {
+		if b, err := s.r.Peek(2); err == nil && string(b) == "}}" {
+			break
+		}
+		r, err := s.readRune()
+		if err != nil {
+			break
+		}
+		buf = append(buf, r)
+	}
+
+	s.pos, s.typ, s.tok = start, TokenIdent, string(buf)
+	return true
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScannerPosMultiline(t *testing.T) {
+	src := "set hidden true\nset preview true\nquit\n"
+	s := newScanner(strings.NewReader(src))
+
+	type want struct {
+		typ  TokenType
+		tok  string
+		line int
+		col  int
+	}
+
+	wants := []want{
+		{TokenIdent, "set", 1, 1},
+		{TokenIdent, "hidden", 1, 5},
+		{TokenIdent, "true", 1, 12},
+		{TokenEOL, "\n", 1, 16},
+		{TokenIdent, "set", 2, 1},
+		{TokenIdent, "preview", 2, 5},
+		{TokenIdent, "true", 2, 13},
+		{TokenEOL, "\n", 2, 17},
+		{TokenIdent, "quit", 3, 1},
+		{TokenEOL, "\n", 3, 5},
+	}
+
+	s.scan()
+	for i, w := range wants {
+		if s.typ != w.typ || s.tok != w.tok || s.pos.Line != w.line || s.pos.Column != w.col {
+			t.Fatalf("token %d: got {%v %q %d:%d}, want {%v %q %d:%d}",
+				i, s.typ, s.tok, s.pos.Line, s.pos.Column, w.typ, w.tok, w.line, w.col)
+		}
+		s.scan()
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+// Visitor visits nodes of an Expr tree. Visit is called for e before its
+// children are visited; if it returns nil, the children of e are skipped.
+type Visitor interface {
+	Visit(e Expr) (w Visitor)
+}
+
+// Walk traverses an Expr tree in depth-first order, calling v.Visit for
+// each node. If v.Visit(e) returns a non-nil Visitor w, Walk continues
+// into the children of e using w.
+func Walk(e Expr, v Visitor) {
+	if e == nil || v == nil {
+		return
+	}
+
+	if v = v.Visit(e); v == nil {
+		return
+	}
+
+	switch e := e.(type) {
+	case *SetExpr, *CallExpr, *ExecExpr, *SourceExpr:
+		// leaves, no children
+	case *MapExpr:
+		Walk(e.expr, v)
+	case *CmdExpr:
+		Walk(e.expr, v)
+	case *ListExpr:
+		for _, child := range e.exprs {
+			Walk(child, v)
+		}
+	}
+}